@@ -7,6 +7,8 @@ import "fmt"
 type Client struct {
 	addr     string
 	password string
+
+	keepAlive *KeepAliveConn
 }
 
 // NewClient creates and returns a configured RCON client.
@@ -17,15 +19,44 @@ func NewClient(addr, password string) *Client {
 	}
 }
 
-// Send establishes a new authenticated connection to the Minecraft
-// server and transmits requested command. Not all commands generate
-// a response from the server. Any response from the server is returned
-// to requester. If connection failure occurs an error is returned.
+// UsePersistentConnection switches Send to reuse a single long-lived,
+// auto-reconnecting connection instead of dialing a fresh connection
+// per command. This avoids the per-command TCP and authentication
+// handshake, which matters for high-frequency callers such as metrics
+// scrapers polling every few seconds.
+func (c *Client) UsePersistentConnection() error {
+	kac, err := KeepAlive(c.addr, c.password)
+	if err != nil {
+		return fmt.Errorf("failed to establish persistent connection: %w", err)
+	}
+
+	c.keepAlive = kac
+	return nil
+}
+
+// Close releases the persistent connection established by
+// UsePersistentConnection, if any. It is a no-op otherwise.
+func (c *Client) Close() error {
+	if c.keepAlive == nil {
+		return nil
+	}
+
+	return c.keepAlive.Close()
+}
+
+// Send transmits the requested command and returns any response from
+// the server. Not all commands generate a response. If connection
+// failure occurs an error is returned.
 //
-// This function is concurrency-safe since each command sent to the
-// Minecraft server creates a new connection. Upon completion of the
-// request the established connection is closed.
+// Unless UsePersistentConnection has been called, Send establishes a
+// new authenticated connection to the Minecraft server per command and
+// closes it upon completion, which keeps this function concurrency-safe
+// without any shared connection state.
 func (c *Client) Send(command string) (string, error) {
+	if c.keepAlive != nil {
+		return c.keepAlive.SendCommand(command)
+	}
+
 	conn, err := Dial(c.addr, c.password)
 	if err != nil {
 		return "", fmt.Errorf("failed to establish connection: %w", err)
@@ -1,35 +1,85 @@
 package rcon
 
 import (
+	"bufio"
+	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 const (
 	DefaultPort uint16 = 25575
 )
 
-// Conn represents a remote RCON connection to a Minecraft server.
+// Flavor selects the RCON dialect a Conn speaks to its server.
+type Flavor int
+
+const (
+	// MinecraftRCON targets Minecraft's RCON implementation, which
+	// terminates a fragmented response with a synthetic
+	// "Unknown request 5" packet rather than following the documented
+	// Source spec.
+	MinecraftRCON Flavor = iota
+
+	// SourceRCON targets a generic Valve Source-engine server
+	// following the documented protocol exactly: a fragmented
+	// response is terminated by mirroring back an empty
+	// SERVERDATA_RESPONSE_VALUE packet sent immediately after the
+	// request.
+	SourceRCON
+)
+
+// Conn represents a remote RCON connection to a server.
 //
 // The RCON connection allows server administrators to remotely
-// execute commands on Minecraft servers.
+// execute commands. A Conn is safe for concurrent use: multiple
+// goroutines may call SendCommand/SendCommandContext at once, queueing
+// for a turn rather than dialing a connection each. Requests are still
+// processed one at a time end-to-end, since Minecraft's server cannot
+// handle queued request packets, but inbound packets are dispatched to
+// the right caller by packet ID, so a caller whose ctx is canceled
+// mid-request unblocks on its own without disturbing whoever holds the
+// turn or is queued behind it.
 type Conn struct {
-	conn     net.Conn
-	mutex    sync.Mutex
-	packets  chan *Packet
-	isClosed bool
+	conn   net.Conn
+	reader *bufio.Reader
+	flavor Flavor
+
+	// turn is a 1-buffered semaphore granting exclusive use of the
+	// wire for one full request/response exchange at a time, since
+	// Minecraft's server cannot handle queued request packets.
+	turn chan struct{}
+
+	pendingMu sync.Mutex
+	pending   map[int32]chan *Packet
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+	isClosed  atomic.Bool
+}
+
+// Dialer configures how Dial connects and authenticates to an RCON
+// server.
+type Dialer struct {
+	// Flavor selects the termination strategy used to detect the end
+	// of a fragmented response. Defaults to MinecraftRCON.
+	Flavor Flavor
 }
 
 // Dial connects and authenticates to the specified URL.
 //
 // The underlying transport layer connection is created along
 // with the configured RCON connection.
-func Dial(addr, password string) (*Conn, error) {
+func (d Dialer) Dial(addr, password string) (*Conn, error) {
 	u, err := url.Parse(addr)
 	if err != nil {
 		return nil, err
@@ -51,20 +101,45 @@ func Dial(addr, password string) (*Conn, error) {
 		return nil, err
 	}
 
-	return NewConn(c, password)
+	return newConn(c, password, d.Flavor)
 }
 
-// NewConn wraps transport layer connection with RCON configuration.
+// Dial connects and authenticates to the specified URL using the
+// default Minecraft RCON dialect. Use Dialer.Dial or DialSource to
+// connect to a generic Source-engine server instead.
+func Dial(addr, password string) (*Conn, error) {
+	return Dialer{Flavor: MinecraftRCON}.Dial(addr, password)
+}
+
+// DialSource connects and authenticates to the specified URL using
+// the Source RCON dialect, as documented by the Valve spec.
+func DialSource(addr, password string) (*Conn, error) {
+	return Dialer{Flavor: SourceRCON}.Dial(addr, password)
+}
+
+// NewConn wraps transport layer connection with RCON configuration
+// using the default Minecraft RCON dialect.
 //
 // RCON authentication is performed as part of connection configuration.
 // Failed authentication closes the transport layer connection.
 func NewConn(c net.Conn, password string) (*Conn, error) {
+	return newConn(c, password, MinecraftRCON)
+}
+
+// newConn wraps transport layer connection with RCON configuration.
+//
+// RCON authentication is performed as part of connection configuration.
+// Failed authentication closes the transport layer connection.
+func newConn(c net.Conn, password string, flavor Flavor) (*Conn, error) {
 	conn := &Conn{
-		conn:     c,
-		mutex:    sync.Mutex{},
-		packets:  make(chan *Packet),
-		isClosed: false,
+		conn:    c,
+		reader:  bufio.NewReader(c),
+		flavor:  flavor,
+		turn:    make(chan struct{}, 1),
+		pending: make(map[int32]chan *Packet),
+		done:    make(chan struct{}),
 	}
+	conn.turn <- struct{}{}
 
 	conn.start()
 
@@ -82,21 +157,50 @@ func NewConn(c net.Conn, password string) (*Conn, error) {
 // cannot execute until the previous completes. All connection errors
 // result in the connection being closed.
 func (c *Conn) SendCommand(command string) (string, error) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	return c.SendCommandContext(context.Background(), command)
+}
 
-	if c.isClosed {
-		return "", errors.New("connection closed")
+// SendCommandContext sends RCON command to server and returns response,
+// same as SendCommand, but respects ctx's cancellation/deadline while
+// waiting for a turn to use the connection and while waiting for the
+// server's response.
+//
+// Multiple goroutines may call SendCommandContext concurrently on the
+// same Conn: each call queues for its turn and is woken individually,
+// by packet ID, once its own response arrives. Requests are still
+// processed one at a time end-to-end (Minecraft's server cannot handle
+// queued request packets), so this does not pipeline commands, but it
+// does let a single long-lived Conn back concurrent callers, such as an
+// HTTP handler or bot, without a per-request TCP handshake, and lets a
+// caller's ctx expiring mid-request unblock just that caller instead of
+// tearing down the connection for everyone else.
+func (c *Conn) SendCommandContext(ctx context.Context, command string) (string, error) {
+	if err := c.acquireTurn(ctx); err != nil {
+		return "", err
 	}
+	defer c.releaseTurn()
 
 	req := NewPacket(CommandPacket, command)
+	ch := make(chan *Packet, 1)
+	c.register(req.ID, ch)
+	defer c.unregister(req.ID)
+
 	if err := c.writePacket(req); err != nil {
 		_ = c.Close()
 		return "", fmt.Errorf("failed writing packet: %w", err)
 	}
 
-	resp, err := c.readPackets()
+	resp, err := c.readPackets(ctx, ch)
 	if err != nil {
+		// ctx expiring mid-request is the caller's concern, not the
+		// connection's: the request ID is unregistered by the deferred
+		// call above on return, so a late response is safely dropped by
+		// dispatch. Closing
+		// the shared connection here would fail every other caller.
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return "", err
+		}
+
 		_ = c.Close()
 		return "", fmt.Errorf("failed reading packets: %w", err)
 	}
@@ -111,51 +215,181 @@ func (c *Conn) SendCommand(command string) (string, error) {
 	return sb.String(), nil
 }
 
+// ErrCommandNotSplittable is returned by SendLarge when a single line
+// of command still exceeds MaxPacketSize and cannot be safely split
+// any further.
+var ErrCommandNotSplittable = errors.New("rcon: command line exceeds maximum packet size and cannot be split")
+
+// SendLarge sends command, same as SendCommand, but transparently
+// splits it into multiple sub-requests at newline boundaries if its
+// serialized size would exceed MaxPacketSize. Responses from each
+// sub-request are concatenated, in order, separated by newlines.
+//
+// Splitting only works for commands composed of independently
+// executable lines, such as a multi-line /execute script or a series
+// of command calls emitted one per line. If a single line still
+// exceeds MaxPacketSize, ErrCommandNotSplittable is returned.
+func (c *Conn) SendLarge(command string) (string, error) {
+	if len(command)+14 <= MaxPacketSize {
+		return c.SendCommand(command)
+	}
+
+	lines := strings.Split(command, "\n")
+	responses := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if len(line)+14 > MaxPacketSize {
+			return "", ErrCommandNotSplittable
+		}
+
+		resp, err := c.SendCommand(line)
+		if err != nil {
+			return "", err
+		}
+
+		responses = append(responses, resp)
+	}
+
+	return strings.Join(responses, "\n"), nil
+}
+
 // IsClosed returns whether the RCON connection is closed.
 //
 // It is possible that an RCON connection becomes closed due to the
 // server hanging up or other connection errors.
 func (c *Conn) IsClosed() bool {
-	return c.isClosed
+	return c.isClosed.Load()
 }
 
 // Close closes the connection.
 // Any blocked command executions will be unblocked and return errors.
 func (c *Conn) Close() error {
-	c.isClosed = true
+	c.closeOnce.Do(func() {
+		c.closeErr = errors.New("connection closed")
+		c.isClosed.Store(true)
+		close(c.done)
+	})
 	return c.conn.Close()
 }
 
-// start begins reading response packets asynchronously from connection.
+// acquireTurn blocks until the caller holds exclusive use of the wire
+// for a full request/response exchange, ctx is done, or the connection
+// is closed.
+func (c *Conn) acquireTurn(ctx context.Context) error {
+	select {
+	case <-c.turn:
+		return nil
+	case <-c.done:
+		return c.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// releaseTurn returns exclusive use of the wire for the next caller.
+func (c *Conn) releaseTurn() {
+	select {
+	case c.turn <- struct{}{}:
+	default:
+	}
+}
+
+// register associates packet ID with ch, so that inbound packets
+// carrying that ID are dispatched to ch.
+func (c *Conn) register(id int32, ch chan *Packet) {
+	c.pendingMu.Lock()
+	c.pending[id] = ch
+	c.pendingMu.Unlock()
+}
+
+// unregister removes the dispatch association created by register.
+func (c *Conn) unregister(id int32) {
+	c.pendingMu.Lock()
+	delete(c.pending, id)
+	c.pendingMu.Unlock()
+}
+
+// start begins reading response packets asynchronously from connection
+// and dispatching them to their registered waiter by packet ID.
 //
-// Read packets are queued in channel for later response processing.
-// Errors reading packets result in the connection being closed.
+// Errors reading packets result in the connection being closed, which
+// unblocks every pending waiter with an error.
 func (c *Conn) start() {
 	go func() {
 		for {
 			packet, err := c.readPacket()
 			if err != nil {
-				_ = c.Close()
-				close(c.packets)
+				_ = c.closeWithErr(fmt.Errorf("connection lost: %w", err))
 				return
 			}
 
-			c.packets <- packet
+			c.dispatch(packet)
 		}
 	}()
 }
 
+// closeWithErr closes the connection, recording err as the reason
+// surfaced to callers still waiting on a turn or a response.
+func (c *Conn) closeWithErr(err error) error {
+	c.closeOnce.Do(func() {
+		c.closeErr = err
+		c.isClosed.Store(true)
+		close(c.done)
+	})
+	return c.conn.Close()
+}
+
+// dispatch delivers p to its registered waiter, if any. Packets with
+// no registered waiter (e.g. delivered after their caller gave up) are
+// dropped.
+func (c *Conn) dispatch(p *Packet) {
+	c.pendingMu.Lock()
+	ch, ok := c.pending[p.ID]
+	c.pendingMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	select {
+	case ch <- p:
+	case <-c.done:
+	}
+}
+
+// recv waits for the next packet delivered to ch, ctx to be done, or
+// the connection to close, whichever happens first.
+func (c *Conn) recv(ctx context.Context, ch chan *Packet) (*Packet, error) {
+	select {
+	case p := <-ch:
+		return p, nil
+	case <-c.done:
+		return nil, c.closeErr
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // authenticate performs RCON login for connection.
 //
 // Error is returned if authentication is unsuccessful or
 // there are issues reading or writing to the connection.
 func (c *Conn) authenticate(password string) error {
 	req := NewPacket(LoginPacket, password)
+
+	ch := make(chan *Packet, 2)
+	c.register(req.ID, ch)
+	defer c.unregister(req.ID)
+
+	// A failed login is mirrored back with ID -1 rather than the
+	// request's ID, so the failure packet is routed the same way.
+	c.register(-1, ch)
+	defer c.unregister(-1)
+
 	if err := c.writePacket(req); err != nil {
 		return fmt.Errorf("failed writing packet: %w", err)
 	}
 
-	resp, err := c.readPackets()
+	resp, err := c.readAuthResponse(context.Background(), ch, req.ID)
 	if err != nil {
 		return fmt.Errorf("failed reading packets: %w", err)
 	}
@@ -163,14 +397,47 @@ func (c *Conn) authenticate(password string) error {
 	// Check response packet ID for failed login. Packet with
 	// the same request ID represents successful authentication.
 	// Packet with ID of -1 represents failed authentication.
-	if len(resp) != 1 || resp[0].ID != req.ID {
+	if resp.ID != req.ID {
 		return errors.New("invalid password/response")
 	}
 
 	return nil
 }
 
-// readPackets returns slice of response packets following a request.
+// readAuthResponse returns the login response packet for the request
+// with the given ID.
+//
+// Source servers precede SERVERDATA_AUTH_RESPONSE with an empty
+// SERVERDATA_RESPONSE_VALUE packet mirroring the request ID, which is
+// discarded before the actual auth response is returned. Minecraft
+// servers reply with the auth response directly.
+func (c *Conn) readAuthResponse(ctx context.Context, ch chan *Packet, requestID int32) (*Packet, error) {
+	for {
+		p, err := c.recv(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.flavor == SourceRCON && p.Kind == ResponsePacket && p.ID == requestID {
+			continue
+		}
+
+		return p, nil
+	}
+}
+
+// readPackets returns slice of response packets following a request,
+// using the termination strategy appropriate for the Conn's Flavor.
+func (c *Conn) readPackets(ctx context.Context, ch chan *Packet) ([]*Packet, error) {
+	if c.flavor == SourceRCON {
+		return c.readPacketsSource(ctx, ch)
+	}
+
+	return c.readPacketsMinecraft(ctx, ch)
+}
+
+// readPacketsMinecraft returns slice of response packets following a
+// request, using Minecraft's non-spec-compliant termination trick.
 //
 // Since responses can be fragmented across multiple packets, all
 // requests are accompanied by a single no-op "termination" packet
@@ -187,14 +454,21 @@ func (c *Conn) authenticate(password string) error {
 // "termination" packet. This group of packets represents the response
 // to a single request packet. The ID of each packet will match the
 // corresponding request packet ID.
-func (c *Conn) readPackets() ([]*Packet, error) {
+func (c *Conn) readPacketsMinecraft(ctx context.Context, ch chan *Packet) ([]*Packet, error) {
 	packets := make([]*Packet, 0)
-	for p := range c.packets {
+	for {
+		p, err := c.recv(ctx, ch)
+		if err != nil {
+			return nil, err
+		}
+
 		// Send termination packet if it has not been sent.
 		if len(packets) == 0 {
 			tp := NewPacket(TerminationPacket, "MESSAGE-END")
-			tb, _ := Marshal(tp)
+			c.register(tp.ID, ch)
+			defer c.unregister(tp.ID)
 
+			tb, _ := Marshal(tp)
 			if _, err := c.conn.Write(tb); err != nil {
 				return nil, fmt.Errorf("failed writing termination packet: %w", err)
 			}
@@ -210,23 +484,76 @@ func (c *Conn) readPackets() ([]*Packet, error) {
 	return packets, nil
 }
 
-// readPacket reads from connection and creates next packet.
-func (c *Conn) readPacket() (*Packet, error) {
-	buf := make([]byte, 1)
-	data := make([]byte, 0)
-
-	// The minimum length of a RCON packet is 14 bytes and is terminated
-	// with two null bytes at the end. Bytes are read one at a time from
-	// the connection until a complete packet has been read.
-	for len(data) < 14 || data[len(data)-1] != 0 || data[len(data)-2] != 0 {
-		_, err := c.conn.Read(buf)
+// readPacketsSource returns slice of response packets following a
+// request, using the documented Source RCON workaround for detecting
+// the end of a fragmented response.
+//
+// Since Source servers do not support the Minecraft "Unknown request 5"
+// trick, an empty SERVERDATA_RESPONSE_VALUE packet is sent immediately
+// after the request packet, rather than waiting for a first response
+// fragment, since a command that produces no output would otherwise
+// never yield one and the probe would never be sent. The server
+// mirrors this packet back once all response fragments have been
+// sent, and that mirrored packet is used to detect the end of the
+// response instead of being included in it.
+func (c *Conn) readPacketsSource(ctx context.Context, ch chan *Packet) ([]*Packet, error) {
+	tp := NewPacket(ResponsePacket, "")
+	probeID := tp.ID
+	c.register(probeID, ch)
+	defer c.unregister(probeID)
+
+	tb, err := Marshal(tp)
+	if err != nil {
+		return nil, fmt.Errorf("failed marshaling termination probe: %w", err)
+	}
+
+	if _, err := c.conn.Write(tb); err != nil {
+		return nil, fmt.Errorf("failed writing termination probe: %w", err)
+	}
+
+	packets := make([]*Packet, 0)
+	for {
+		p, err := c.recv(ctx, ch)
 		if err != nil {
 			return nil, err
 		}
 
-		data = append(data, buf[0])
+		if p.ID == probeID && p.Payload == "" {
+			break
+		}
+
+		packets = append(packets, p)
 	}
 
+	return packets, nil
+}
+
+// readPacket reads from connection and creates next packet.
+//
+// The 4-byte little-endian length prefix is read first, then exactly
+// that many more bytes are read in a single call, rather than scanning
+// byte-by-byte for a terminator. The trailing two null bytes are still
+// validated by Unmarshal, but only as a sanity check on the decoded
+// frame, which also makes this immune to payloads containing embedded
+// null bytes.
+func (c *Conn) readPacket() (*Packet, error) {
+	prefix := make([]byte, 4)
+	if _, err := io.ReadFull(c.reader, prefix); err != nil {
+		return nil, err
+	}
+
+	length := binary.LittleEndian.Uint32(prefix)
+	if length > MaxResponseFragmentSize+10 {
+		return nil, ErrResponseFragmentTooLarge
+	}
+
+	rest := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, rest); err != nil {
+		return nil, err
+	}
+
+	data := append(prefix, rest...)
+
 	p := &Packet{}
 	if err := Unmarshal(data, p); err != nil {
 		return nil, err
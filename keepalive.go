@@ -0,0 +1,301 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// BackoffConfig configures the exponential backoff KeepAliveConn uses
+// between reconnect attempts.
+type BackoffConfig struct {
+	// BaseDelay is the delay before the second reconnect attempt; the
+	// first attempt is made immediately.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between reconnect attempts before Jitter
+	// is applied, so a jittered delay can exceed it by up to the Jitter
+	// fraction. Zero means no cap.
+	MaxDelay time.Duration
+
+	// Jitter randomizes each delay by up to this fraction (0-1), so
+	// that many clients reconnecting after the same outage don't all
+	// retry in lockstep.
+	Jitter float64
+
+	// MaxAttempts caps the number of reconnect attempts before giving
+	// up. Zero means unlimited attempts.
+	MaxAttempts int
+}
+
+// DefaultBackoffConfig is used when a KeepAliveDialer is not given an
+// explicit BackoffConfig.
+var DefaultBackoffConfig = BackoffConfig{
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+	MaxAttempts: 0,
+}
+
+// delay returns the backoff delay after n+1 failed reconnect attempts,
+// i.e. the wait before reconnect attempt n+1 (0-indexed).
+func (b BackoffConfig) delay(n int) time.Duration {
+	d := float64(b.BaseDelay) * math.Pow(2, float64(n))
+	if b.MaxDelay > 0 && d > float64(b.MaxDelay) {
+		d = float64(b.MaxDelay)
+	}
+
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if d < 0 {
+		d = 0
+	}
+
+	return time.Duration(d)
+}
+
+// KeepAliveDialer configures a resilient, long-lived connection that
+// transparently redials and re-authenticates on failure and probes the
+// server on an interval to detect half-open connections early.
+type KeepAliveDialer struct {
+	// Flavor selects the RCON dialect, same as Dialer.Flavor.
+	Flavor Flavor
+
+	// Backoff configures the delay between reconnect attempts.
+	// Defaults to DefaultBackoffConfig.
+	Backoff BackoffConfig
+
+	// KeepAliveInterval is how often a keep-alive command is sent to
+	// detect a half-open connection. Zero disables keep-alive probing.
+	KeepAliveInterval time.Duration
+
+	// KeepAliveCommand is the command sent as a keep-alive probe.
+	// Defaults to "list".
+	KeepAliveCommand string
+}
+
+// Dial connects and authenticates to the specified URL, returning a
+// KeepAliveConn that transparently reconnects on failure.
+func (d KeepAliveDialer) Dial(addr, password string) (*KeepAliveConn, error) {
+	backoff := d.Backoff
+	if backoff == (BackoffConfig{}) {
+		backoff = DefaultBackoffConfig
+	}
+
+	command := d.KeepAliveCommand
+	if command == "" {
+		command = "list"
+	}
+
+	k := &KeepAliveConn{
+		addr:              addr,
+		password:          password,
+		flavor:            d.Flavor,
+		backoff:           backoff,
+		keepAliveInterval: d.KeepAliveInterval,
+		keepAliveCommand:  command,
+		stopCh:            make(chan struct{}),
+	}
+
+	if err := k.connect(nil); err != nil {
+		return nil, err
+	}
+
+	if k.keepAliveInterval > 0 {
+		go k.keepAliveLoop()
+	}
+
+	return k, nil
+}
+
+// KeepAlive connects and authenticates to the specified URL using the
+// default Minecraft RCON dialect and backoff settings, returning a
+// KeepAliveConn that transparently reconnects on failure.
+func KeepAlive(addr, password string) (*KeepAliveConn, error) {
+	return KeepAliveDialer{}.Dial(addr, password)
+}
+
+// KeepAliveConn is a long-lived RCON connection that transparently
+// redials and re-authenticates, using stored credentials, on read/write
+// error or server hangup. This avoids paying a per-command TCP and
+// authentication handshake for high-frequency callers such as metrics
+// scrapers or bots.
+type KeepAliveConn struct {
+	addr     string
+	password string
+	flavor   Flavor
+	backoff  BackoffConfig
+
+	keepAliveInterval time.Duration
+	keepAliveCommand  string
+
+	mutex  sync.Mutex
+	conn   *Conn
+	closed bool
+	stopCh chan struct{}
+
+	// reconnectMu serializes reconnect attempts, so that concurrent
+	// callers observing the same failed conn don't each dial and
+	// authenticate a new connection.
+	reconnectMu sync.Mutex
+}
+
+// SendCommand sends command over the underlying connection, same as
+// Conn.SendCommand. If the underlying connection has failed, it is
+// transparently redialed and re-authenticated with exponential backoff
+// before the command is retried once.
+func (k *KeepAliveConn) SendCommand(command string) (string, error) {
+	return k.SendCommandContext(context.Background(), command)
+}
+
+// SendCommandContext is the context-aware variant of SendCommand.
+func (k *KeepAliveConn) SendCommandContext(ctx context.Context, command string) (string, error) {
+	conn, err := k.activeConn()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := conn.SendCommandContext(ctx, command)
+	if err == nil {
+		return resp, nil
+	}
+
+	if !conn.IsClosed() {
+		return "", err
+	}
+
+	if err := k.reconnect(conn); err != nil {
+		return "", fmt.Errorf("command failed and reconnect failed: %w", err)
+	}
+
+	conn, err = k.activeConn()
+	if err != nil {
+		return "", err
+	}
+
+	return conn.SendCommandContext(ctx, command)
+}
+
+// IsClosed returns whether the keep-alive connection has been closed.
+func (k *KeepAliveConn) IsClosed() bool {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+	return k.closed
+}
+
+// Close stops keep-alive probing and closes the underlying connection.
+// Any blocked command executions will be unblocked and return errors.
+func (k *KeepAliveConn) Close() error {
+	k.mutex.Lock()
+	if k.closed {
+		k.mutex.Unlock()
+		return nil
+	}
+	k.closed = true
+	conn := k.conn
+	k.mutex.Unlock()
+
+	close(k.stopCh)
+
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// activeConn returns the current underlying connection, or an error if
+// the keep-alive connection has been closed.
+func (k *KeepAliveConn) activeConn() (*Conn, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if k.closed {
+		return nil, errors.New("keep-alive connection closed")
+	}
+
+	return k.conn, nil
+}
+
+// connect dials and authenticates a fresh underlying connection,
+// replacing prev. If prev is non-nil, it is closed once superseded, so
+// that a connection made obsolete by a reconnect never leaks its TCP
+// socket or reader goroutine.
+func (k *KeepAliveConn) connect(prev *Conn) error {
+	c, err := (Dialer{Flavor: k.flavor}).Dial(k.addr, k.password)
+	if err != nil {
+		return err
+	}
+
+	k.mutex.Lock()
+	k.conn = c
+	k.mutex.Unlock()
+
+	if prev != nil {
+		_ = prev.Close()
+	}
+
+	return nil
+}
+
+// reconnect redials and re-authenticates in place of failed, retrying
+// with exponential backoff until it succeeds, the keep-alive connection
+// is closed, or Backoff.MaxAttempts is exhausted.
+//
+// reconnect is single-flighted via reconnectMu: if failed has already
+// been superseded by the time a caller gets a turn (because another
+// goroutine observing the same failure reconnected first), it returns
+// immediately rather than dialing a redundant connection.
+func (k *KeepAliveConn) reconnect(failed *Conn) error {
+	k.reconnectMu.Lock()
+	defer k.reconnectMu.Unlock()
+
+	k.mutex.Lock()
+	current := k.conn
+	k.mutex.Unlock()
+
+	if current != failed {
+		return nil
+	}
+
+	var lastErr error
+	for attempt := 0; k.backoff.MaxAttempts == 0 || attempt < k.backoff.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(k.backoff.delay(attempt - 1)):
+			case <-k.stopCh:
+				return errors.New("keep-alive connection closed")
+			}
+		}
+
+		if err := k.connect(failed); err != nil {
+			lastErr = err
+			continue
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("exceeded max reconnect attempts: %w", lastErr)
+}
+
+// keepAliveLoop periodically issues a cheap command to detect
+// half-open TCP connections before a real caller hits the failure.
+func (k *KeepAliveConn) keepAliveLoop() {
+	ticker := time.NewTicker(k.keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_, _ = k.SendCommand(k.keepAliveCommand)
+		case <-k.stopCh:
+			return
+		}
+	}
+}
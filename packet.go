@@ -2,8 +2,10 @@ package rcon
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"strings"
 	"unicode"
 )
@@ -19,13 +21,26 @@ const (
 	TerminalResponse = "Unknown request 5"
 )
 
-// Packet IDs are typically incremented sequentially. Count tracks
-// the current ID and is incremented when a new Packet is created.
-var (
-	count int32 = 0
+type Kind uint32
+
+const (
+	// MaxPacketSize is the largest a serialized request packet may be
+	// on the wire before a compliant server is expected to drop the
+	// connection.
+	MaxPacketSize = 1460
+
+	// MaxResponseFragmentSize is the largest payload a single response
+	// fragment packet may carry.
+	MaxResponseFragmentSize = 4096
 )
 
-type Kind uint32
+// ErrPayloadTooLarge is returned by Marshal when a Packet's serialized
+// size would exceed MaxPacketSize.
+var ErrPayloadTooLarge = errors.New("rcon: payload exceeds maximum packet size")
+
+// ErrResponseFragmentTooLarge indicates a server sent a response
+// fragment larger than MaxResponseFragmentSize.
+var ErrResponseFragmentTooLarge = errors.New("rcon: response fragment exceeds maximum size")
 
 // Packet defines RCON protocol encoding.
 type Packet struct {
@@ -35,18 +50,30 @@ type Packet struct {
 	Payload string
 }
 
-// NewPacket creates and returns a Packet. Packet length and ID
-// are automatically set to satisfy protocol requirements.
+// NewPacket creates and returns a Packet. Packet length is automatically
+// set to satisfy protocol requirements and ID is a cryptographically
+// random int32, so that callers can safely demultiplex concurrent
+// requests by ID instead of relying on a monotonic counter.
 func NewPacket(kind Kind, payload string) *Packet {
-	count++
 	return &Packet{
 		Length:  uint32(len(payload) + 10),
-		ID:      count,
+		ID:      randomID(),
 		Kind:    kind,
 		Payload: payload,
 	}
 }
 
+// randomID returns a cryptographically random int32 suitable for use
+// as a Packet ID.
+func randomID() int32 {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("rcon: failed to read random packet id: %v", err))
+	}
+
+	return int32(binary.LittleEndian.Uint32(b[:]))
+}
+
 // Marshal returns the RCON encoding of Packet p. If Packet p
 // is nil or contains invalid payload, Marshal returns an error.
 func Marshal(p *Packet) ([]byte, error) {
@@ -58,6 +85,10 @@ func Marshal(p *Packet) ([]byte, error) {
 		return nil, errors.New("invalid packet provided")
 	}
 
+	if int(p.Length)+4 > MaxPacketSize {
+		return nil, ErrPayloadTooLarge
+	}
+
 	for i := 0; i < len(p.Payload); i++ {
 		if p.Payload[i] > unicode.MaxASCII {
 			return nil, errors.New("payload contains non-ASCII characters")
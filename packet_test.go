@@ -1,6 +1,10 @@
 package rcon
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestMarshalAndUnmarshal(t *testing.T) {
 	p := NewPacket(CommandPacket, "test")
@@ -20,3 +24,12 @@ func TestMarshalAndUnmarshal(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestMarshalPayloadTooLarge(t *testing.T) {
+	p := NewPacket(CommandPacket, strings.Repeat("a", MaxPacketSize))
+
+	_, err := Marshal(p)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package rcon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LogLine is a single parsed server console log line.
+type LogLine struct {
+	Time    time.Time
+	Level   string
+	Source  string
+	Message string
+}
+
+// vanillaLogPattern matches vanilla Minecraft server log lines, e.g.:
+//
+//	[09:00:00] [Server thread/INFO]: Done (1.234s)! For help, type "help"
+var vanillaLogPattern = regexp.MustCompile(`^\[(\d{2}:\d{2}:\d{2})] \[([^/]+)/(\w+)]: (.*)$`)
+
+// ParseVanillaLogLine parses a single line of vanilla Minecraft server
+// console output into a LogLine. Since vanilla log lines only carry a
+// time of day, now supplies the date used to build the line's
+// timestamp, and should be the time the line was received.
+func ParseVanillaLogLine(now time.Time, line string) (LogLine, bool) {
+	m := vanillaLogPattern.FindStringSubmatch(line)
+	if m == nil {
+		return LogLine{}, false
+	}
+
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", now.Format("2006-01-02")+" "+m[1], now.Location())
+	if err != nil {
+		return LogLine{}, false
+	}
+
+	return LogLine{
+		Time:    t,
+		Source:  m[2],
+		Level:   m[3],
+		Message: m[4],
+	}, true
+}
+
+// SubscribeOptions configures Conn.SubscribeWithOptions.
+type SubscribeOptions struct {
+	// PollCommand is the RCON command issued on each poll to fetch new
+	// console output. Vanilla RCON has no generic log-tailing command,
+	// so this must be set to whatever command the target server or
+	// plugin exposes for retrieving recent log lines. Left empty,
+	// SubscribeWithOptions still runs but never yields a LogLine.
+	PollCommand string
+
+	// PollInterval is how often PollCommand is issued. Defaults to
+	// time.Second.
+	PollInterval time.Duration
+}
+
+// Subscribe is SubscribeWithOptions with default options: it polls
+// every second but, absent a configured PollCommand, never yields a
+// LogLine. Use SubscribeWithOptions to target a server's log-tailing
+// command.
+func (c *Conn) Subscribe(ctx context.Context) (<-chan LogLine, error) {
+	return c.SubscribeWithOptions(ctx, SubscribeOptions{})
+}
+
+// SubscribeWithOptions polls the connection for new console output and
+// yields parsed LogLines on the returned channel as they arrive. The
+// channel is closed when ctx is done or the connection closes.
+func (c *Conn) SubscribeWithOptions(ctx context.Context, opts SubscribeOptions) (<-chan LogLine, error) {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+
+	out := make(chan LogLine)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(opts.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+				if opts.PollCommand == "" {
+					continue
+				}
+
+				resp, err := c.SendCommandContext(ctx, opts.PollCommand)
+				if err != nil {
+					return
+				}
+
+				now := time.Now()
+				for _, line := range strings.Split(resp, "\n") {
+					ll, ok := ParseVanillaLogLine(now, line)
+					if !ok {
+						continue
+					}
+
+					select {
+					case out <- ll:
+					case <-ctx.Done():
+						return
+					case <-c.done:
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// ScoreboardEntry is a single player's score for a scoreboard objective.
+type ScoreboardEntry struct {
+	Player string
+	Score  int
+}
+
+// ScoreboardChange describes a player's score changing between two
+// successive scoreboard polls. OldScore is zero when the player did not
+// appear in the previous poll.
+type ScoreboardChange struct {
+	Player   string
+	OldScore int
+	NewScore int
+}
+
+// scoreboardLinePattern matches a single row of the response to
+// "/scoreboard players list <objective>", e.g. "- Alice: 5".
+var scoreboardLinePattern = regexp.MustCompile(`^- (\S+): (-?\d+)`)
+
+// ParseScoreboardList parses the response to
+// "/scoreboard players list <objective>" into its entries.
+func ParseScoreboardList(resp string) []ScoreboardEntry {
+	var entries []ScoreboardEntry
+	for _, line := range strings.Split(resp, "\n") {
+		m := scoreboardLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		score, err := strconv.Atoi(m[2])
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, ScoreboardEntry{Player: m[1], Score: score})
+	}
+
+	return entries
+}
+
+// scoreboardGetPattern matches the response to
+// "/scoreboard players get <player> <objective>", e.g. "Alice has 5 [money]".
+var scoreboardGetPattern = regexp.MustCompile(`^(\S+) has (-?\d+) \[(.+)]$`)
+
+// ParseScoreboardGet parses the response to
+// "/scoreboard players get <player> <objective>" into a ScoreboardEntry.
+func ParseScoreboardGet(resp string) (ScoreboardEntry, bool) {
+	m := scoreboardGetPattern.FindStringSubmatch(strings.TrimSpace(resp))
+	if m == nil {
+		return ScoreboardEntry{}, false
+	}
+
+	score, err := strconv.Atoi(m[2])
+	if err != nil {
+		return ScoreboardEntry{}, false
+	}
+
+	return ScoreboardEntry{Player: m[1], Score: score}, true
+}
+
+// DataGetResult is the parsed response to "/data get <target> [path]".
+// Raw holds the value in Minecraft's stringified NBT (SNBT) format, as
+// returned by the server.
+type DataGetResult struct {
+	Target string
+	// Path holds the kind of data named in the response, such as
+	// "entity" or "block"; the response does not echo the queried path.
+	Path string
+	Raw  string
+}
+
+// dataGetPattern matches the response to "/data get <target> [path]",
+// e.g. "Steve has the following entity data: 20" or
+// "block 0 64 0 has the following block data: {Items: []}".
+var dataGetPattern = regexp.MustCompile(`^(.+?) has the following(?: (\S+) data)?: (.+)$`)
+
+// ParseDataGet parses the response to "/data get <target> [path]".
+func ParseDataGet(resp string) (DataGetResult, bool) {
+	m := dataGetPattern.FindStringSubmatch(strings.TrimSpace(resp))
+	if m == nil {
+		return DataGetResult{}, false
+	}
+
+	return DataGetResult{Target: m[1], Path: m[2], Raw: m[3]}, true
+}
+
+// JSON decodes r.Raw as JSON. This only succeeds where the SNBT text
+// happens to be valid JSON, such as a plain number or quoted string:
+// most NBT compounds and lists use syntax that is not valid JSON, such
+// as unquoted keys and numeric type suffixes like "20.0f", and are
+// returned as a decode error rather than being reinterpreted.
+func (r DataGetResult) JSON() (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(r.Raw), &v); err != nil {
+		return nil, fmt.Errorf("rcon: /data get value is not valid JSON: %w", err)
+	}
+
+	return v, nil
+}
+
+// WatchScoreboard polls "/scoreboard players list <objective>" on the
+// given interval and emits a ScoreboardChange for every player whose
+// score differs from the previous poll. The first poll only seeds the
+// baseline and emits nothing, since every player would otherwise be
+// reported as a spurious change. The returned channel is closed when
+// ctx is done or the connection closes.
+func (c *Conn) WatchScoreboard(ctx context.Context, objective string, interval time.Duration) (<-chan ScoreboardChange, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	out := make(chan ScoreboardChange)
+	command := fmt.Sprintf("scoreboard players list %s", objective)
+
+	go func() {
+		defer close(out)
+
+		var prev map[string]int
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+				resp, err := c.SendCommandContext(ctx, command)
+				if err != nil {
+					return
+				}
+
+				next := make(map[string]int)
+				for _, e := range ParseScoreboardList(resp) {
+					next[e.Player] = e.Score
+
+					if prev == nil {
+						continue
+					}
+
+					if old, ok := prev[e.Player]; !ok || old != e.Score {
+						change := ScoreboardChange{Player: e.Player, NewScore: e.Score}
+						if ok {
+							change.OldScore = old
+						}
+
+						select {
+						case out <- change:
+						case <-ctx.Done():
+							return
+						case <-c.done:
+							return
+						}
+					}
+				}
+
+				prev = next
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package rcon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseVanillaLogLine(t *testing.T) {
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	ll, ok := ParseVanillaLogLine(now, `[09:00:00] [Server thread/INFO]: Done (1.234s)!`)
+	if !ok {
+		t.Fatal("expected line to parse")
+	}
+
+	if ll.Source != "Server thread" || ll.Level != "INFO" || ll.Message != "Done (1.234s)!" {
+		t.Fail()
+	}
+
+	if _, ok := ParseVanillaLogLine(now, "not a log line"); ok {
+		t.Fail()
+	}
+}
+
+func TestParseScoreboardList(t *testing.T) {
+	resp := "Showing 2 tracked players for objective money:\n- Alice: 5\n- Bob: -3\n"
+
+	entries := ParseScoreboardList(resp)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Player != "Alice" || entries[0].Score != 5 {
+		t.Fail()
+	}
+
+	if entries[1].Player != "Bob" || entries[1].Score != -3 {
+		t.Fail()
+	}
+}
+
+func TestParseScoreboardGet(t *testing.T) {
+	entry, ok := ParseScoreboardGet("Alice has 5 [money]")
+	if !ok {
+		t.Fatal("expected response to parse")
+	}
+
+	if entry.Player != "Alice" || entry.Score != 5 {
+		t.Fail()
+	}
+
+	if _, ok := ParseScoreboardGet("not a scoreboard response"); ok {
+		t.Fail()
+	}
+}
+
+func TestParseDataGet(t *testing.T) {
+	result, ok := ParseDataGet("Steve has the following entity data: 20")
+	if !ok {
+		t.Fatal("expected response to parse")
+	}
+
+	if result.Target != "Steve" || result.Path != "entity" || result.Raw != "20" {
+		t.Fail()
+	}
+
+	v, err := result.JSON()
+	if err != nil {
+		t.Fatalf("expected scalar NBT value to decode as JSON: %v", err)
+	}
+
+	if v != float64(20) {
+		t.Fail()
+	}
+
+	compound, ok := ParseDataGet("Steve has the following entity data: {Health:20.0f}")
+	if !ok {
+		t.Fatal("expected response to parse")
+	}
+
+	if _, err := compound.JSON(); err == nil {
+		t.Fatal("expected SNBT compound to fail JSON decoding")
+	}
+}